@@ -0,0 +1,155 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// aabb is an axis-aligned bounding box used to prune ray/primitive tests in
+// the BVH below.
+type aabb struct {
+	min, max Vec3f
+}
+
+func surroundingBox(a, b aabb) aabb {
+	return aabb{
+		min: Vec3f{
+			float32(math.Min(float64(a.min[0]), float64(b.min[0]))),
+			float32(math.Min(float64(a.min[1]), float64(b.min[1]))),
+			float32(math.Min(float64(a.min[2]), float64(b.min[2]))),
+		},
+		max: Vec3f{
+			float32(math.Max(float64(a.max[0]), float64(b.max[0]))),
+			float32(math.Max(float64(a.max[1]), float64(b.max[1]))),
+			float32(math.Max(float64(a.max[2]), float64(b.max[2]))),
+		},
+	}
+}
+
+func (b aabb) center() Vec3f {
+	return scale(add(b.min, b.max), 0.5)
+}
+
+func (b aabb) longestAxis() int {
+	d := sub(b.max, b.min)
+	axis := 0
+	if d[1] > d[axis] {
+		axis = 1
+	}
+	if d[2] > d[axis] {
+		axis = 2
+	}
+	return axis
+}
+
+// hit is the slab test: it reports whether the ray enters the box before
+// tMax, and if so the distance to the near face (used to order BVH
+// traversal by which child the ray reaches first).
+func (b aabb) hit(origin, direction Vec3f, tMax float32) (ok bool, tmin float32) {
+	tmin, tmax := float32(0), tMax
+	for i := 0; i < 3; i++ {
+		invD := 1 / direction[i]
+		t0 := (b.min[i] - origin[i]) * invD
+		t1 := (b.max[i] - origin[i]) * invD
+		if invD < 0 {
+			t0, t1 = t1, t0
+		}
+		if t0 > tmin {
+			tmin = t0
+		}
+		if t1 < tmax {
+			tmax = t1
+		}
+		if tmax < tmin {
+			return false, 0
+		}
+	}
+	return true, tmin
+}
+
+// bvhNode is an internal node of the bounding volume hierarchy; its children
+// are either further bvhNodes or leaf primitives.
+type bvhNode struct {
+	bounds      aabb
+	left, right Hittable
+}
+
+// BuildBVH arranges objects into a binary AABB tree: at each node it bounds
+// all contained primitives, picks the longest axis of that bound, sorts by
+// centroid along it and splits at the median. The result implements
+// Hittable itself, so castRay can test the whole scene with a single
+// Intersect call instead of a linear O(N) loop.
+func BuildBVH(objects []Hittable) Hittable {
+	if len(objects) == 0 {
+		return nil
+	}
+	// Work on a copy: sort.Slice below reorders in place and callers may
+	// still hold the original slice.
+	return buildBVH(append([]Hittable(nil), objects...))
+}
+
+func buildBVH(objects []Hittable) Hittable {
+	if len(objects) == 1 {
+		return objects[0]
+	}
+
+	bounds := objects[0].Bounds()
+	for _, o := range objects[1:] {
+		bounds = surroundingBox(bounds, o.Bounds())
+	}
+
+	if len(objects) == 2 {
+		return &bvhNode{bounds: bounds, left: objects[0], right: objects[1]}
+	}
+
+	axis := bounds.longestAxis()
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Bounds().center()[axis] < objects[j].Bounds().center()[axis]
+	})
+
+	mid := len(objects) / 2
+	return &bvhNode{
+		bounds: bounds,
+		left:   buildBVH(objects[:mid]),
+		right:  buildBVH(objects[mid:]),
+	}
+}
+
+func (n *bvhNode) Bounds() aabb {
+	return n.bounds
+}
+
+func (n *bvhNode) Intersect(origin, direction Vec3f) (hit bool, t float32, normal Vec3f, mat Material) {
+	if ok, _ := n.bounds.hit(origin, direction, float32(math.MaxFloat32)); !ok {
+		return false, 0, Vec3f{}, Material{}
+	}
+
+	near, far := n.left, n.right
+	nearOK, nearTMin := near.Bounds().hit(origin, direction, float32(math.MaxFloat32))
+	farOK, farTMin := far.Bounds().hit(origin, direction, float32(math.MaxFloat32))
+	if farOK && (!nearOK || farTMin < nearTMin) {
+		near, far = far, near
+		nearOK, farOK = farOK, nearOK
+	}
+
+	bestT := float32(math.MaxFloat32)
+
+	if nearOK {
+		if ok, ct, cn, cm := near.Intersect(origin, direction); ok {
+			hit, t, normal, mat = true, ct, cn, cm
+			bestT = ct
+		}
+	}
+
+	// Only descend into the farther child if its box can possibly beat the
+	// closest hit found so far -- this is the prune.
+	if farOK {
+		if boxOK, _ := far.Bounds().hit(origin, direction, bestT); boxOK {
+			if ok, ct, cn, cm := far.Intersect(origin, direction); ok && ct < bestT {
+				hit, t, normal, mat = true, ct, cn, cm
+			}
+		}
+	}
+
+	return hit, t, normal, mat
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// generateTriangleMesh returns n triangles scattered through a cube, for
+// benchmarking intersection strategies against a mesh-sized primitive
+// count.
+func generateTriangleMesh(n int, seed int64) []Hittable {
+	rng := rand.New(rand.NewSource(seed))
+	triangles := make([]Hittable, 0, n)
+	for i := 0; i < n; i++ {
+		center := Vec3f{
+			rng.Float32()*200 - 100,
+			rng.Float32()*200 - 100,
+			rng.Float32()*200 - 100,
+		}
+		triangles = append(triangles, Triangle{
+			v0:       add(center, Vec3f{0, 0, 0}),
+			v1:       add(center, Vec3f{rng.Float32(), rng.Float32(), rng.Float32()}),
+			v2:       add(center, Vec3f{rng.Float32(), rng.Float32(), rng.Float32()}),
+			material: Material{},
+		})
+	}
+	return triangles
+}
+
+func linearIntersect(origin, direction Vec3f, objects []Hittable) (hit bool, t float32) {
+	closest := float32(math.MaxFloat32)
+	for _, o := range objects {
+		if ok, ct, _, _ := o.Intersect(origin, direction); ok && ct < closest {
+			closest, hit = ct, true
+		}
+	}
+	return hit, closest
+}
+
+func benchmarkRay(b *testing.B, rng *rand.Rand) (origin, direction Vec3f) {
+	b.Helper()
+	return Vec3f{0, 0, -300}, normalize(Vec3f{rng.Float32()*2 - 1, rng.Float32()*2 - 1, 1})
+}
+
+func BenchmarkSceneIntersectLinear(b *testing.B) {
+	triangles := generateTriangleMesh(10000, 1)
+	rng := rand.New(rand.NewSource(2))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		origin, direction := benchmarkRay(b, rng)
+		linearIntersect(origin, direction, triangles)
+	}
+}
+
+func BenchmarkSceneIntersectBVH(b *testing.B) {
+	triangles := generateTriangleMesh(10000, 1)
+	root := BuildBVH(triangles)
+	rng := rand.New(rand.NewSource(2))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		origin, direction := benchmarkRay(b, rng)
+		root.Intersect(origin, direction)
+	}
+}
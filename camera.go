@@ -0,0 +1,64 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Camera turns normalized image coordinates into rays, supporting an
+// arbitrary position/orientation and, via aperture/focusDist, depth-of-field
+// defocus blur.
+type Camera struct {
+	origin          Vec3f
+	lowerLeftCorner Vec3f
+	horizontal      Vec3f
+	vertical        Vec3f
+	u, v            Vec3f
+	lensRadius      float32
+}
+
+// NewCamera builds a Camera looking from lookFrom towards lookAt, with vUp
+// defining the roll. vfov is the vertical field of view in radians, aspect
+// is width/height. aperture and focusDist control depth of field: rays are
+// jittered over a disk of radius aperture/2 and aimed through the focus
+// plane at focusDist, so aperture 0 gives an ordinary pinhole camera.
+func NewCamera(lookFrom, lookAt, vUp Vec3f, vfov, aspect, aperture, focusDist float32) *Camera {
+	halfHeight := float32(math.Tan(float64(vfov) / 2))
+	halfWidth := aspect * halfHeight
+
+	w := normalize(sub(lookFrom, lookAt))
+	u := normalize(cross(vUp, w))
+	v := cross(w, u)
+
+	return &Camera{
+		origin:          lookFrom,
+		lowerLeftCorner: sub(sub(sub(lookFrom, scale(u, halfWidth*focusDist)), scale(v, halfHeight*focusDist)), scale(w, focusDist)),
+		horizontal:      scale(u, 2*halfWidth*focusDist),
+		vertical:        scale(v, 2*halfHeight*focusDist),
+		u:               u,
+		v:               v,
+		lensRadius:      aperture / 2,
+	}
+}
+
+// GetRay returns a ray through normalized image coordinates (s, t), s
+// spanning [0,1] left-to-right and t spanning [0,1] bottom-to-top. rng
+// drives the lens-sampling jitter for depth of field.
+func (c *Camera) GetRay(s, t float32, rng *rand.Rand) (origin, direction Vec3f) {
+	rd := scale(randomInUnitDisk(rng), c.lensRadius)
+	offset := add(scale(c.u, rd[0]), scale(c.v, rd[1]))
+
+	origin = add(c.origin, offset)
+	target := add(c.lowerLeftCorner, add(scale(c.horizontal, s), scale(c.vertical, t)))
+	direction = normalize(sub(target, origin))
+	return
+}
+
+func randomInUnitDisk(rng *rand.Rand) Vec3f {
+	for {
+		p := Vec3f{2*rng.Float32() - 1, 2*rng.Float32() - 1, 0}
+		if dot(p, p) < 1 {
+			return p
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package main
+
+// Hittable is anything a ray can intersect: spheres, planes, triangles, and
+// anything built from them (meshes, acceleration structures).
+type Hittable interface {
+	// Intersect tests the ray origin+t*direction against the primitive. If
+	// it hits, t is the distance along direction to the hit point, normal
+	// is the surface normal there, and mat is the surface material.
+	Intersect(origin, direction Vec3f) (hit bool, t float32, normal Vec3f, mat Material)
+
+	// Bounds returns the primitive's axis-aligned bounding box, used to
+	// build and traverse the BVH.
+	Bounds() aabb
+}
@@ -0,0 +1,57 @@
+package main
+
+import "math"
+
+type Material struct {
+	refractiveIndex  float64
+	diffuseColor     Vec3f
+	albedo           [4]float32
+	specularExponent float64
+}
+
+// refractionAngles resolves the cosine of the incidence angle and the
+// etai/etat refractive indices a ray with direction I sees at a surface
+// with the given normal and refractiveIndex, swapping them (and the
+// normal) if the ray is exiting the surface rather than entering it.
+// refract and fresnelReflectance both need this, since Schlick's
+// approximation and Snell's law are evaluated at the same angle.
+func refractionAngles(I, normal Vec3f, refractiveIndex float64) (cosi, etai, etat float64, n Vec3f) {
+	cosi = float64(-clamp11(dot(I, normal)))
+	etai = 1
+	etat = refractiveIndex
+	n = normal
+	// If the ray is inside the object, swap the indices and invert the normal to get the correct result
+	if cosi < 0 {
+		cosi = -cosi
+		etai, etat = etat, etai
+		n = negate(normal)
+	}
+	return
+}
+
+// Snell's law. tir reports total internal reflection -- the angle is past
+// the critical angle and there is no refracted ray at all, so the caller
+// should route all of the energy into the reflected ray instead.
+func refract(I Vec3f, cosi, etai, etat float64, n Vec3f) (refracted Vec3f, tir bool) {
+	eta := etai / etat
+	k := 1 - eta*eta*(1-cosi*cosi)
+	if k < 0 {
+		return Vec3f{}, true
+	}
+	return add(scale(I, float32(eta)), scale(n, float32(eta*cosi-math.Sqrt(k)))), false
+}
+
+// schlick is Schlick's approximation of the Fresnel reflectance for light
+// crossing a boundary between media of refractive index n1 and n2, hitting
+// it at incidence angle whose cosine is cosi.
+func schlick(cosi, n1, n2 float64) float64 {
+	r0 := (n1 - n2) / (n1 + n2)
+	r0 *= r0
+	return r0 + (1-r0)*math.Pow(1-cosi, 5)
+}
+
+// fresnelReflectance computes the Fresnel reflectance at the angle resolved
+// by refractionAngles.
+func fresnelReflectance(cosi, etai, etat float64) float32 {
+	return float32(schlick(cosi, etai, etat))
+}
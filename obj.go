@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadOBJ parses a minimal subset of the Wavefront OBJ format -- "v" vertex
+// lines and "f" face lines, where each face vertex may be given as a bare
+// index or "v/vt/vn" (only the vertex index is used) -- into a slice of
+// Triangles sharing material. Negative indices are relative to the last
+// vertex seen, per the OBJ spec. Faces with more than 3 vertices are
+// fan-triangulated around their first vertex.
+func LoadOBJ(r io.Reader, material Material) ([]Hittable, error) {
+	var vertices []Vec3f
+	var triangles []Hittable
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "v":
+			v, err := parseOBJVertex(fields)
+			if err != nil {
+				return nil, err
+			}
+			vertices = append(vertices, v)
+		case "f":
+			face, err := parseOBJFace(fields, len(vertices))
+			if err != nil {
+				return nil, err
+			}
+			for i := 1; i+1 < len(face); i++ {
+				triangles = append(triangles, Triangle{
+					v0:       vertices[face[0]],
+					v1:       vertices[face[i]],
+					v2:       vertices[face[i+1]],
+					material: material,
+				})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return triangles, nil
+}
+
+func parseOBJVertex(fields []string) (Vec3f, error) {
+	if len(fields) < 4 {
+		return Vec3f{}, fmt.Errorf("gotrt: malformed vertex line %q", strings.Join(fields, " "))
+	}
+	var v Vec3f
+	for i := 0; i < 3; i++ {
+		f, err := strconv.ParseFloat(fields[i+1], 32)
+		if err != nil {
+			return Vec3f{}, fmt.Errorf("gotrt: malformed vertex line %q: %w", strings.Join(fields, " "), err)
+		}
+		v[i] = float32(f)
+	}
+	return v, nil
+}
+
+// parseOBJFace returns zero-based vertex indices for a face line, resolving
+// negative (relative) indices against vertexCount.
+func parseOBJFace(fields []string, vertexCount int) ([]int, error) {
+	indices := make([]int, 0, len(fields)-1)
+	for _, f := range fields[1:] {
+		idxStr := strings.SplitN(f, "/", 2)[0]
+		idx, err := strconv.Atoi(idxStr)
+		if err != nil {
+			return nil, fmt.Errorf("gotrt: malformed face line %q: %w", strings.Join(fields, " "), err)
+		}
+		if idx < 0 {
+			idx = vertexCount + idx + 1
+		}
+		if idx < 1 || idx > vertexCount {
+			return nil, fmt.Errorf("gotrt: face index out of range in line %q", strings.Join(fields, " "))
+		}
+		indices = append(indices, idx-1)
+	}
+	return indices, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import "math"
+
+// Plane is an axis-aligned, y-level patch of ground with a two-color
+// checkerboard pattern, e.g. the floor the spheres sit on.
+type Plane struct {
+	y        float32
+	halfSize float32 // extent along X, centered on 0
+	zNear    float32 // closer bound along Z (larger value)
+	zFar     float32 // farther bound along Z (smaller value)
+	colorA   Vec3f
+	colorB   Vec3f
+	material Material // diffuseColor is overwritten per checker cell
+}
+
+func (p Plane) Intersect(origin, direction Vec3f) (hit bool, t float32, normal Vec3f, mat Material) {
+	if math.Abs(float64(direction.Y())) < 0.001 {
+		return false, 0, Vec3f{}, Material{}
+	}
+
+	d := -(origin.Y() - p.y) / direction.Y()
+	point := add(origin, scale(direction, d))
+
+	if d <= 0 || math.Abs(float64(point.X())) >= float64(p.halfSize) || point.Z() >= p.zNear || point.Z() <= p.zFar {
+		return false, 0, Vec3f{}, Material{}
+	}
+
+	mat = p.material
+	colorIndicator := int(0.5*point.X()+1000) + int(0.5*point.Z())
+	if (colorIndicator & 1) == 1 {
+		mat.diffuseColor = p.colorB
+	} else {
+		mat.diffuseColor = p.colorA
+	}
+	mat.diffuseColor = scale(mat.diffuseColor, 0.3)
+
+	return true, d, Vec3f{0, 1, 0}, mat
+}
+
+func (p Plane) Bounds() aabb {
+	const thickness = 0.0001 // the plane is flat; give its box a sliver of depth
+	return aabb{
+		min: Vec3f{-p.halfSize, p.y - thickness, p.zFar},
+		max: Vec3f{p.halfSize, p.y + thickness, p.zNear},
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"image"
+	"image/png"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// RenderOptions controls how a Scene is rasterized into a frame buffer.
+type RenderOptions struct {
+	Width    int
+	Height   int
+	TileSize int
+	Workers  int
+	FOV      float64 // vertical field of view, radians
+	Depth    int
+	Samples  int
+
+	LookFrom  Vec3f
+	LookAt    Vec3f
+	VUp       Vec3f
+	Aperture  float32
+	FocusDist float32
+
+	// PreviewSink, if set, is invoked with the in-progress frame buffer
+	// every PreviewEvery completed tiles, so callers can stream a live
+	// preview while rendering.
+	PreviewSink  func(*image.NRGBA)
+	PreviewEvery int
+}
+
+// DefaultRenderOptions mirrors the hard-coded constants the renderer used
+// before it became configurable: a pinhole camera at the origin looking
+// down -Z.
+func DefaultRenderOptions() RenderOptions {
+	return RenderOptions{
+		Width:    1024,
+		Height:   768,
+		TileSize: 32,
+		Workers:  runtime.NumCPU(),
+		FOV:      math.Pi / 2,
+		Depth:    4,
+		Samples:  4,
+
+		LookFrom:  Vec3f{0, 0, 0},
+		LookAt:    Vec3f{0, 0, -1},
+		VUp:       Vec3f{0, 1, 0},
+		Aperture:  0,
+		FocusDist: 1,
+	}
+}
+
+// tile is a rectangular region of the frame buffer, [x0,x1)x[y0,y1).
+type tile struct {
+	x0, y0, x1, y1 int
+}
+
+// Render rasterizes scene into an *image.NRGBA according to opts. The
+// frame buffer is split into opts.TileSize x opts.TileSize tiles, which are
+// pushed onto a buffered channel and drained by opts.Workers goroutines;
+// each worker renders its tiles independently since every pixel is written
+// by exactly one goroutine.
+//
+// If progress is non-nil, it is called after every completed tile with the
+// number done so far and the total. If ctx is cancelled, workers stop
+// picking up new tiles and Render returns the frame buffer as rendered so
+// far, with ctx.Err() available to the caller via ctx.Err().
+func Render(ctx context.Context, scene Scene, opts RenderOptions, progress func(done, total int)) *image.NRGBA {
+	width, height := opts.Width, opts.Height
+	fWidth, fHeight := float32(width), float32(height)
+
+	camera := NewCamera(opts.LookFrom, opts.LookAt, opts.VUp, float32(opts.FOV), fWidth/fHeight, opts.Aperture, opts.FocusDist)
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	tileSize := opts.TileSize
+	if tileSize < 1 {
+		tileSize = width
+	}
+
+	var tileList []tile
+	for y0 := 0; y0 < height; y0 += tileSize {
+		y1 := y0 + tileSize
+		if y1 > height {
+			y1 = height
+		}
+		for x0 := 0; x0 < width; x0 += tileSize {
+			x1 := x0 + tileSize
+			if x1 > width {
+				x1 = width
+			}
+			tileList = append(tileList, tile{x0, y0, x1, y1})
+		}
+	}
+	total := len(tileList)
+
+	tiles := make(chan tile, total)
+	for _, t := range tileList {
+		tiles <- t
+	}
+	close(tiles)
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	var completed int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// Each worker gets its own rand.Source so sampling jitter never
+			// contends on the global rand lock.
+			rng := rand.New(rand.NewSource(int64(w) + 1))
+			for t := range tiles {
+				if ctx.Err() != nil {
+					return
+				}
+				renderTile(img, t, scene, opts, camera, fWidth, fHeight, rng)
+
+				done := atomic.AddInt64(&completed, 1)
+				if progress != nil {
+					progress(int(done), total)
+				}
+				if opts.PreviewSink != nil && opts.PreviewEvery > 0 && int(done)%opts.PreviewEvery == 0 {
+					opts.PreviewSink(img)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	return img
+}
+
+func renderTile(img *image.NRGBA, t tile, scene Scene, opts RenderOptions, camera *Camera, fWidth, fHeight float32, rng *rand.Rand) {
+	samples := opts.Samples
+	if samples < 1 {
+		samples = 1
+	}
+	for y := t.y0; y < t.y1; y++ {
+		for x := t.x0; x < t.x1; x++ {
+			var sum Vec3f
+			for i := 0; i < samples; i++ {
+				s := (float32(x) + rng.Float32()) / fWidth
+				v := 1 - (float32(y)+rng.Float32())/fHeight
+				origin, direction := camera.GetRay(s, v, rng)
+				sum = add(sum, castRay(origin, direction, scene, opts.Depth))
+			}
+			pixel := gammaCorrect(scale(sum, 1/float32(samples)))
+			img.SetNRGBA(x, y, pixel.ToNRGBA())
+		}
+	}
+}
+
+// renderToFile renders scene with opts and writes the result to path as a
+// PNG; it is a thin wrapper around Render for the common CLI use case.
+func renderToFile(ctx context.Context, scene Scene, opts RenderOptions, progress func(done, total int), path string) error {
+	img := Render(ctx, scene, opts, progress)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	if err := png.Encode(writer, img); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
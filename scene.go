@@ -0,0 +1,110 @@
+package main
+
+import "math"
+
+type Light struct {
+	position  Vec3f
+	intensity float32
+}
+
+// Scene is everything castRay needs to trace a ray: the primitives,
+// assembled into a BVH for faster traversal, and the lights illuminating
+// them. It is read-only once handed to Render, so the same Scene can be
+// shared across worker goroutines without synchronization.
+type Scene struct {
+	Root   Hittable
+	Lights []Light
+}
+
+// NewScene builds a Scene from a flat list of primitives, arranging them
+// into a BVH so castRay doesn't need to test every primitive against every
+// ray.
+func NewScene(objects []Hittable, lights []Light) Scene {
+	return Scene{Root: BuildBVH(objects), Lights: lights}
+}
+
+func sceneIntersect(origin, direction Vec3f, root Hittable) (intersects bool, hit, N Vec3f, material Material) {
+	if root == nil {
+		return false, Vec3f{}, Vec3f{}, Material{}
+	}
+
+	ok, distance, normal, mat := root.Intersect(origin, direction)
+	if !ok || distance >= 1000 {
+		return false, Vec3f{}, Vec3f{}, Material{}
+	}
+
+	return true, add(origin, scale(direction, distance)), normal, mat
+}
+
+func castRay(origin, direction Vec3f, scene Scene, depth int) (color Vec3f) {
+	bgColor := Vec3f{0.2, 0.7, 0.8}
+
+	intersects, point, normal, material := sceneIntersect(origin, direction, scene.Root)
+	if depth < 1 || !intersects {
+		return bgColor
+	}
+
+	reflectDirection := normalize(reflect(direction, normal))
+	// Not to hit the object itself with reflection check
+	pointCorrection := scale(normal, 0.001)
+	if dot(reflectDirection, normal) < 0 {
+		pointCorrection = negate(pointCorrection)
+	}
+	reflectOrigin := add(point, pointCorrection)
+	reflectColor := castRay(reflectOrigin, reflectDirection, scene, depth-1)
+
+	cosi, etai, etat, n := refractionAngles(direction, normal, material.refractiveIndex)
+	refractDirection, tir := refract(direction, cosi, etai, etat, n)
+	fresnel := fresnelReflectance(cosi, etai, etat)
+	var refractColor Vec3f
+	if tir {
+		// No refracted ray exists past the critical angle; all the
+		// transparency budget goes into the reflection instead.
+		fresnel = 1
+	} else {
+		refractDirection = normalize(refractDirection)
+		pointCorrection = scale(normal, 0.001)
+		if dot(refractDirection, normal) < 0 {
+			pointCorrection = negate(pointCorrection)
+		}
+		refractOrigin := add(point, pointCorrection)
+		refractColor = castRay(refractOrigin, refractDirection, scene, depth-1)
+	}
+
+	diffuseLightIntensity, specularLightIntensity := float32(0), float32(0)
+	for _, light := range scene.Lights {
+		lightDirection := normalize(sub(light.position, point))
+		lightDistance := sub(light.position, point).length()
+
+		// Not to hit the object itself with shadow check
+		pointCorrection := scale(normal, 0.001)
+		if dot(lightDirection, normal) < 0 {
+			pointCorrection = negate(pointCorrection)
+		}
+		shadowOrigin := add(point, pointCorrection)
+
+		shadowIntersects, shadowPoint, _, _ := sceneIntersect(shadowOrigin, lightDirection, scene.Root)
+		if shadowIntersects && sub(shadowPoint, shadowOrigin).length() < lightDistance {
+			// We hit something before the light ray reaches the point
+			continue
+		}
+
+		diffuseLightIntensity += light.intensity * float32(math.Max(0, float64(dot(lightDirection, normal))))
+
+		viewAngleToLightReflectionAngleValue := math.Max(0, float64(-dot(reflect(negate(lightDirection), normal), direction)))
+		specularLightIntensity += float32(math.Pow(viewAngleToLightReflectionAngleValue, material.specularExponent)) * light.intensity
+	}
+
+	// The transparency budget (albedo[3]) is split between the reflected
+	// and refracted rays by the Fresnel term instead of a fixed ratio, so
+	// e.g. the glass sphere turns mirror-like at grazing angles.
+	reflectWeight := material.albedo[2] + fresnel*material.albedo[3]
+	refractWeight := (1 - fresnel) * material.albedo[3]
+
+	white := Vec3f{1, 1, 1}
+	return accumulate(
+		scale(material.diffuseColor, diffuseLightIntensity*material.albedo[0]),
+		scale(white, specularLightIntensity*material.albedo[1]),
+		scale(reflectColor, reflectWeight),
+		scale(refractColor, refractWeight))
+}
@@ -0,0 +1,45 @@
+package main
+
+import "math"
+
+type Sphere struct {
+	center   Vec3f
+	radius   float32
+	material Material
+}
+
+func (s Sphere) rayIntersects(origin, direction Vec3f) (bool, float32) {
+	L := sub(s.center, origin)
+	tca := dot(L, direction)
+	d2 := dot(L, L) - tca*tca
+	r2 := s.radius * s.radius
+
+	if d2 > r2 {
+		return false, float32(math.MaxFloat32)
+	}
+
+	thc := float32(math.Sqrt(float64(r2 - d2)))
+	t0 := tca - thc
+	t1 := tca + thc
+	if t0 < 0 {
+		t0 = t1
+	}
+	if t0 < 0 {
+		return false, t0
+	}
+	return true, t0
+}
+
+func (s Sphere) Intersect(origin, direction Vec3f) (hit bool, t float32, normal Vec3f, mat Material) {
+	ok, distance := s.rayIntersects(origin, direction)
+	if !ok {
+		return false, 0, Vec3f{}, Material{}
+	}
+	point := add(origin, scale(direction, distance))
+	return true, distance, normalize(sub(point, s.center)), s.material
+}
+
+func (s Sphere) Bounds() aabb {
+	r := Vec3f{s.radius, s.radius, s.radius}
+	return aabb{min: sub(s.center, r), max: add(s.center, r)}
+}
@@ -0,0 +1,64 @@
+package main
+
+import "math"
+
+// Triangle is a single flat-shaded triangle, e.g. one face of a mesh loaded
+// via LoadOBJ.
+type Triangle struct {
+	v0, v1, v2 Vec3f
+	material   Material
+}
+
+// Intersect uses the Möller–Trumbore algorithm.
+func (tr Triangle) Intersect(origin, direction Vec3f) (hit bool, t float32, normal Vec3f, mat Material) {
+	const epsilon = 1e-7
+
+	edge1 := sub(tr.v1, tr.v0)
+	edge2 := sub(tr.v2, tr.v0)
+	pvec := cross(direction, edge2)
+	det := dot(edge1, pvec)
+	if math.Abs(float64(det)) < epsilon {
+		return false, 0, Vec3f{}, Material{}
+	}
+	invDet := 1 / det
+
+	tvec := sub(origin, tr.v0)
+	u := dot(tvec, pvec) * invDet
+	if u < 0 || u > 1 {
+		return false, 0, Vec3f{}, Material{}
+	}
+
+	qvec := cross(tvec, edge1)
+	v := dot(direction, qvec) * invDet
+	if v < 0 || u+v > 1 {
+		return false, 0, Vec3f{}, Material{}
+	}
+
+	distance := dot(edge2, qvec) * invDet
+	if distance < 0.001 {
+		return false, 0, Vec3f{}, Material{}
+	}
+
+	return true, distance, normalize(cross(edge1, edge2)), tr.material
+}
+
+func (tr Triangle) Bounds() aabb {
+	min3 := func(a, b, c float32) float32 {
+		return float32(math.Min(float64(a), math.Min(float64(b), float64(c))))
+	}
+	max3 := func(a, b, c float32) float32 {
+		return float32(math.Max(float64(a), math.Max(float64(b), float64(c))))
+	}
+	return aabb{
+		min: Vec3f{
+			min3(tr.v0[0], tr.v1[0], tr.v2[0]),
+			min3(tr.v0[1], tr.v1[1], tr.v2[1]),
+			min3(tr.v0[2], tr.v1[2], tr.v2[2]),
+		},
+		max: Vec3f{
+			max3(tr.v0[0], tr.v1[0], tr.v2[0]),
+			max3(tr.v0[1], tr.v1[1], tr.v2[1]),
+			max3(tr.v0[2], tr.v1[2], tr.v2[2]),
+		},
+	}
+}
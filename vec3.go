@@ -0,0 +1,139 @@
+package main
+
+import (
+	"image/color"
+	"math"
+)
+
+type Vec3f [3]float32
+
+func sub(lhs, rhs Vec3f) (ret Vec3f) {
+	for i := range lhs {
+		ret[i] = lhs[i] - rhs[i]
+	}
+	return
+}
+
+func add(lhs, rhs Vec3f) (ret Vec3f) {
+	for i := range lhs {
+		ret[i] = lhs[i] + rhs[i]
+	}
+	return
+}
+
+func accumulate(vectors ...Vec3f) Vec3f {
+	ret := Vec3f{0, 0, 0}
+	for _, v := range vectors {
+		for i := range v {
+			ret[i] += v[i]
+		}
+	}
+	return ret
+}
+
+func scale(v Vec3f, f float32) (ret Vec3f) {
+	for i := range v {
+		ret[i] = v[i] * f
+	}
+	return
+}
+
+func dot(lhs, rhs Vec3f) float32 {
+	ret := float32(0)
+	for i := range lhs {
+		ret += lhs[i] * rhs[i]
+	}
+	return ret
+}
+
+func cross(lhs, rhs Vec3f) Vec3f {
+	return Vec3f{
+		lhs[1]*rhs[2] - lhs[2]*rhs[1],
+		lhs[2]*rhs[0] - lhs[0]*rhs[2],
+		lhs[0]*rhs[1] - lhs[1]*rhs[0],
+	}
+}
+
+func (v Vec3f) length() float32 {
+	return float32(math.Sqrt(float64(dot(v, v))))
+}
+
+func clamp01(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+func clamp11(v float32) float32 {
+	if v < -1 {
+		return -1
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+func normalize(v Vec3f) (ret Vec3f) {
+	l := v.length()
+	for i, p := range v {
+		ret[i] = p / l
+	}
+	return
+}
+
+func negate(v Vec3f) (ret Vec3f) {
+	for i, p := range v {
+		ret[i] = -p
+	}
+	return
+}
+
+func reflect(I, normal Vec3f) Vec3f {
+	return sub(I, scale(normal, 2*dot(I, normal)))
+}
+
+func (v Vec3f) color(i int) uint8 {
+	return uint8(clamp01(v[i]) * 255)
+}
+
+func (v Vec3f) R() uint8 {
+	return v.color(0)
+}
+func (v Vec3f) G() uint8 {
+	return v.color(1)
+}
+func (v Vec3f) B() uint8 {
+	return v.color(2)
+}
+
+func (v Vec3f) X() float32 {
+	return v[0]
+}
+func (v Vec3f) Y() float32 {
+	return v[1]
+}
+func (v Vec3f) Z() float32 {
+	return v[2]
+}
+
+func (v Vec3f) ToNRGBA() color.NRGBA {
+	return color.NRGBA{v.R(), v.G(), v.B(), 255}
+}
+
+func NewVec3f(x, y, z float32) Vec3f {
+	return [3]float32{x, y, z}
+}
+
+// gammaCorrect applies gamma-2 correction (sqrt) to a linear color so it can
+// be quantized for display.
+func gammaCorrect(v Vec3f) (ret Vec3f) {
+	for i, p := range v {
+		ret[i] = float32(math.Sqrt(float64(clamp01(p))))
+	}
+	return
+}